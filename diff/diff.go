@@ -0,0 +1,382 @@
+// Package diff parses unified diffs as produced by `git show`/`git diff`
+// into structured FileDiffs, so rules can inspect what changed (renames,
+// binary blobs, files under a path, hunk sizes) without regex-hacking raw
+// diff text themselves.
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Hunk is a single `@@ -a,b +c,d @@` change region within a FileDiff.
+type Hunk struct {
+	OrigStartLine int32
+	OrigLines     int32
+	NewStartLine  int32
+	NewLines      int32
+	Section       string
+	Body          []byte
+}
+
+// FileDiff is one file's entry in a unified diff: the `diff --git a/... b/...`
+// header, its extended header lines, and its hunks.
+type FileDiff struct {
+	OrigName string
+	NewName  string
+	OrigMode os.FileMode
+	NewMode  os.FileMode
+	Extended []string
+	Hunks    []Hunk
+
+	IsBinary bool
+	IsRename bool
+	IsCopy   bool
+	IsNew    bool
+	IsDelete bool
+}
+
+// ShowParsed returns the structured diff of a commit, equivalent to piping
+// `git show --format= --no-color commit` through ParseMultiFileDiff.
+func ShowParsed(commit string) ([]*FileDiff, error) {
+	cmd := exec.Command("git", "--no-pager", "show", "--format=", "--no-color", commit)
+	if debug() {
+		logrus.Infof("[diff] cmd: %q", strings.Join(cmd.Args, " "))
+	}
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	diffs, err := ParseMultiFileDiff(stdout)
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+func debug() bool {
+	return len(os.Getenv("DEBUG")) > 0
+}
+
+// ParseMultiFileDiff parses zero or more unified file diffs, as found in
+// the output of `git show`/`git diff`, from r.
+func ParseMultiFileDiff(r io.Reader) ([]*FileDiff, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	var diffs []*FileDiff
+	var cur *FileDiff
+	var hunk *Hunk
+	var body []string
+
+	flushHunk := func() {
+		if hunk == nil {
+			return
+		}
+		hunk.Body = []byte(strings.Join(body, ""))
+		cur.Hunks = append(cur.Hunks, *hunk)
+		hunk = nil
+		body = nil
+	}
+	flushFile := func() {
+		flushHunk()
+		if cur != nil {
+			diffs = append(diffs, cur)
+		}
+		cur = nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flushFile()
+			cur = &FileDiff{}
+			orig, newName, ok := parseGitHeaderNames(line)
+			if ok {
+				cur.OrigName, cur.NewName = orig, newName
+			}
+			continue
+		}
+
+		if cur == nil {
+			// Anything before the first "diff --git" line (e.g. a commit
+			// message, when the caller forgot --format=) isn't part of the
+			// diff.
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			flushHunk()
+			h, section, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			hunk = h
+			hunk.Section = section
+			continue
+		case hunk != nil:
+			body = append(body, line+"\n")
+			continue
+		case strings.HasPrefix(line, "--- "):
+			if cur.OrigName == "" {
+				if name, ok := parseDiffPathLine(line, "--- ", "a/"); ok {
+					cur.OrigName = name
+				}
+			}
+			continue
+		case strings.HasPrefix(line, "+++ "):
+			if cur.NewName == "" {
+				if name, ok := parseDiffPathLine(line, "+++ ", "b/"); ok {
+					cur.NewName = name
+				}
+			}
+			continue
+		case strings.HasPrefix(line, "Binary files "):
+			cur.IsBinary = true
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "new file mode "):
+			cur.IsNew = true
+			cur.NewMode = parseFileMode(line, "new file mode ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "deleted file mode "):
+			cur.IsDelete = true
+			cur.OrigMode = parseFileMode(line, "deleted file mode ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "old mode "):
+			cur.OrigMode = parseFileMode(line, "old mode ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "new mode "):
+			cur.NewMode = parseFileMode(line, "new mode ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "similarity index "):
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "rename from "):
+			cur.IsRename = true
+			cur.OrigName = strings.TrimPrefix(line, "rename from ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "rename to "):
+			cur.IsRename = true
+			cur.NewName = strings.TrimPrefix(line, "rename to ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "copy from "):
+			cur.IsCopy = true
+			cur.OrigName = strings.TrimPrefix(line, "copy from ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "copy to "):
+			cur.IsCopy = true
+			cur.NewName = strings.TrimPrefix(line, "copy to ")
+			cur.Extended = append(cur.Extended, line)
+			continue
+		case strings.HasPrefix(line, "index "):
+			cur.Extended = append(cur.Extended, line)
+			continue
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flushFile()
+	return diffs, nil
+}
+
+// parseGitHeaderNames pulls the a/... and b/... paths out of a
+// "diff --git a/foo b/bar" line. git double-quotes (and C-style escapes)
+// either path when it contains a space, a double quote, or other
+// special characters, so each side is parsed as a quoted or bare token
+// independently.
+func parseGitHeaderNames(line string) (orig, newName string, ok bool) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+
+	var aTok string
+	if strings.HasPrefix(rest, `"`) {
+		path, n, err := unquoteGitPath(rest)
+		if err != nil {
+			return "", "", false
+		}
+		aTok, rest = path, strings.TrimPrefix(rest[n:], " ")
+	} else {
+		i := strings.Index(rest, " b/")
+		if i < 0 {
+			return "", "", false
+		}
+		aTok, rest = rest[:i], rest[i+1:]
+	}
+	if !strings.HasPrefix(aTok, "a/") {
+		return "", "", false
+	}
+
+	bTok := rest
+	if strings.HasPrefix(rest, `"`) {
+		path, _, err := unquoteGitPath(rest)
+		if err != nil {
+			return "", "", false
+		}
+		bTok = path
+	}
+	if !strings.HasPrefix(bTok, "b/") {
+		return "", "", false
+	}
+
+	return aTok[len("a/"):], bTok[len("b/"):], true
+}
+
+// parseDiffPathLine pulls the path out of a "--- a/foo"/"+++ b/foo" style
+// line, used as a fallback when the "diff --git" header's path(s) didn't
+// parse (e.g. quoting this package doesn't recognize). Returns ok=false,
+// "" for a line that doesn't match linePrefix+pathPrefix (or its quoted
+// form); returns "", true for "/dev/null" (a new or deleted file's
+// missing side).
+func parseDiffPathLine(line, linePrefix, pathPrefix string) (name string, ok bool) {
+	field := strings.TrimPrefix(line, linePrefix)
+	if field == "/dev/null" {
+		return "", true
+	}
+	if strings.HasPrefix(field, `"`) {
+		path, _, err := unquoteGitPath(field)
+		if err != nil || !strings.HasPrefix(path, pathPrefix) {
+			return "", false
+		}
+		return path[len(pathPrefix):], true
+	}
+	if !strings.HasPrefix(field, pathPrefix) {
+		return "", false
+	}
+	return field[len(pathPrefix):], true
+}
+
+// unquoteGitPath unquotes a double-quoted, C-style-escaped path the way
+// git's quote_path (see quote.c) renders one: s must start with a double
+// quote, and unquoteGitPath returns the decoded path plus the number of
+// bytes of s its closing quote consumed.
+func unquoteGitPath(s string) (path string, consumed int, err error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", 0, fmt.Errorf("diff: not a quoted path: %q", s)
+	}
+	var b strings.Builder
+	i := 1
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String(), i + 1, nil
+		case c == '\\' && i+1 < len(s):
+			switch next := s[i+1]; next {
+			case '"', '\\':
+				b.WriteByte(next)
+				i += 2
+			case 'n':
+				b.WriteByte('\n')
+				i += 2
+			case 't':
+				b.WriteByte('\t')
+				i += 2
+			default:
+				if i+4 <= len(s) && isOctalTriplet(s[i+1:i+4]) {
+					v, _ := strconv.ParseUint(s[i+1:i+4], 8, 8)
+					b.WriteByte(byte(v))
+					i += 4
+				} else {
+					b.WriteByte(next)
+					i += 2
+				}
+			}
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return "", 0, fmt.Errorf("diff: unterminated quoted path %q", s)
+}
+
+func isOctalTriplet(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '7' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseHunkHeader parses a "@@ -origStart,origLines +newStart,newLines @@ section"
+// line.
+func parseHunkHeader(line string) (*Hunk, string, error) {
+	rest := strings.TrimPrefix(line, "@@ ")
+	end := strings.Index(rest, " @@")
+	if end < 0 {
+		return nil, "", fmt.Errorf("diff: malformed hunk header %q", line)
+	}
+	ranges := strings.Fields(rest[:end])
+	if len(ranges) != 2 {
+		return nil, "", fmt.Errorf("diff: malformed hunk header %q", line)
+	}
+	origStart, origLines, err := parseHunkRange(ranges[0], "-")
+	if err != nil {
+		return nil, "", err
+	}
+	newStart, newLines, err := parseHunkRange(ranges[1], "+")
+	if err != nil {
+		return nil, "", err
+	}
+	return &Hunk{
+		OrigStartLine: origStart,
+		OrigLines:     origLines,
+		NewStartLine:  newStart,
+		NewLines:      newLines,
+	}, strings.TrimSpace(rest[end+len(" @@"):]), nil
+}
+
+func parseHunkRange(s, prefix string) (start, lines int32, err error) {
+	s = strings.TrimPrefix(s, prefix)
+	parts := strings.SplitN(s, ",", 2)
+	start64, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("diff: malformed hunk range %q: %v", s, err)
+	}
+	lines64 := int64(1)
+	if len(parts) == 2 {
+		lines64, err = strconv.ParseInt(parts[1], 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("diff: malformed hunk range %q: %v", s, err)
+		}
+	}
+	return int32(start64), int32(lines64), nil
+}
+
+func parseFileMode(line, prefix string) os.FileMode {
+	s := strings.TrimPrefix(line, prefix)
+	s = strings.Fields(s)[0]
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0
+	}
+	return os.FileMode(mode)
+}
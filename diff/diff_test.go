@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/foo.txt b/foo.txt
+index 1234567..89abcde 100644
+--- a/foo.txt
++++ b/foo.txt
+@@ -1,2 +1,3 @@
+ unchanged
+-removed
++added
++added again
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+`
+
+func TestParseMultiFileDiff(t *testing.T) {
+	diffs, err := ParseMultiFileDiff(strings.NewReader(sampleDiff))
+	if err != nil {
+		t.Fatalf("ParseMultiFileDiff: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("got %d FileDiffs, want 2", len(diffs))
+	}
+
+	first := diffs[0]
+	if first.OrigName != "foo.txt" || first.NewName != "foo.txt" {
+		t.Errorf("names = %q/%q, want foo.txt/foo.txt", first.OrigName, first.NewName)
+	}
+	if len(first.Hunks) != 1 {
+		t.Fatalf("got %d hunks, want 1", len(first.Hunks))
+	}
+	h := first.Hunks[0]
+	if h.OrigStartLine != 1 || h.OrigLines != 2 || h.NewStartLine != 1 || h.NewLines != 3 {
+		t.Errorf("hunk header parsed wrong: %+v", h)
+	}
+	if !strings.Contains(string(h.Body), "+added again\n") {
+		t.Errorf("hunk body missing expected line: %q", h.Body)
+	}
+
+	second := diffs[1]
+	if !second.IsRename {
+		t.Errorf("second FileDiff should be a rename")
+	}
+	if second.OrigName != "old.txt" || second.NewName != "new.txt" {
+		t.Errorf("rename names = %q/%q, want old.txt/new.txt", second.OrigName, second.NewName)
+	}
+}
+
+const quotedPathDiff = `diff --git "a/vendor/some lib/x.go" "b/vendor/some lib/x.go"
+new file mode 100644
+index 0000000..1234567
+--- /dev/null
++++ "b/vendor/some lib/x.go"
+@@ -0,0 +1 @@
++package lib
+`
+
+func TestParseMultiFileDiffQuotedPaths(t *testing.T) {
+	diffs, err := ParseMultiFileDiff(strings.NewReader(quotedPathDiff))
+	if err != nil {
+		t.Fatalf("ParseMultiFileDiff: %v", err)
+	}
+	if len(diffs) != 1 {
+		t.Fatalf("got %d FileDiffs, want 1", len(diffs))
+	}
+
+	d := diffs[0]
+	if !d.IsNew {
+		t.Errorf("FileDiff should be a new file")
+	}
+	const want = "vendor/some lib/x.go"
+	if d.OrigName != want || d.NewName != want {
+		t.Errorf("names = %q/%q, want %q/%q", d.OrigName, d.NewName, want, want)
+	}
+}
@@ -0,0 +1,66 @@
+package git
+
+import "os"
+
+// Backend is the pluggable implementation behind the package-level
+// CommitsTyped, Check, Show, HeadCommit and FetchHeadCommit functions.
+// Commits, CommitsInRange and LogCommit do not go through it; they always
+// run `git log` against the working directory's Repo. ShellBackend (the
+// default) shells out to the `git` binary; GoGitBackend walks the
+// repository natively via go-git, which avoids forking hundreds of
+// processes and works even when `git` isn't on PATH.
+type Backend interface {
+	Commits(commitrange string) ([]Commit, error)
+	Check(hash string) ([]byte, error)
+	Show(hash string) ([]byte, error)
+	Head() (string, error)
+	FetchHead() (string, error)
+}
+
+// ShellBackend implements Backend the way this package has always worked:
+// by shelling out to the `git` binary.
+type ShellBackend struct{}
+
+// Commits implements Backend.
+func (ShellBackend) Commits(commitrange string) ([]Commit, error) {
+	entries, err := CommitsInRange(commitrange)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]Commit, len(entries))
+	for i, e := range entries {
+		c, err := e.Parse()
+		if err != nil {
+			return nil, err
+		}
+		commits[i] = *c
+	}
+	return commits, nil
+}
+
+// Check implements Backend.
+func (ShellBackend) Check(hash string) ([]byte, error) { return shellCheck(hash) }
+
+// Show implements Backend.
+func (ShellBackend) Show(hash string) ([]byte, error) { return shellShow(hash) }
+
+// Head implements Backend.
+func (ShellBackend) Head() (string, error) { return shellHeadCommit() }
+
+// FetchHead implements Backend.
+func (ShellBackend) FetchHead() (string, error) { return shellFetchHeadCommit() }
+
+var defaultBackend Backend = ShellBackend{}
+
+// SetDefaultBackend overrides the Backend used by the package-level
+// functions. Callers embedding this package in server-side hook binaries
+// can use it to select GoGitBackend at startup.
+func SetDefaultBackend(b Backend) {
+	defaultBackend = b
+}
+
+func init() {
+	if os.Getenv("GIT_VALIDATION_BACKEND") == "gogit" {
+		defaultBackend = &GoGitBackend{}
+	}
+}
@@ -0,0 +1,274 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GoGitBackend implements Backend natively via go-git instead of shelling
+// out to the `git` binary. This avoids forking a process per commit (or per
+// field, previously) and works in environments where `git` isn't on PATH,
+// such as containers and CI shims that only embed this library.
+//
+// GoGitBackend opens the repository rooted at Dir (the working directory,
+// if Dir is empty) on each call, since this package's other backends are
+// likewise stateless between calls.
+//
+// go.mod pins go-git to v5.11.0 rather than latest: newer go-git releases
+// require Go >= 1.25, which would raise this module's floor for everyone,
+// not just GoGitBackend users.
+type GoGitBackend struct {
+	Dir string
+}
+
+func (b *GoGitBackend) open() (*git.Repository, error) {
+	dir := b.Dir
+	if dir == "" {
+		dir = "."
+	}
+	return git.PlainOpen(dir)
+}
+
+// Commits implements Backend for a bare revision, a `since..to` range, and
+// a `since...to` range, matching `git log`'s own set semantics for each
+// rather than approximating them: a plain ForEach-until-we-see-since walk
+// is only correct for linear history, and silently returns the wrong
+// commit set (including, in the `...` case, falling through to the whole
+// history) the moment a merge is involved.
+func (b *GoGitBackend) Commits(commitrange string) ([]Commit, error) {
+	repo, err := b.open()
+	if err != nil {
+		return nil, err
+	}
+
+	if i := strings.Index(commitrange, "..."); i >= 0 {
+		return symmetricDiffCommits(repo, commitrange[:i], commitrange[i+3:])
+	}
+	if i := strings.Index(commitrange, ".."); i >= 0 {
+		return rangeCommits(repo, commitrange[:i], commitrange[i+2:])
+	}
+
+	to, err := resolve(repo, commitrange)
+	if err != nil {
+		return nil, err
+	}
+	return logCommits(repo, to, nil)
+}
+
+// rangeCommits implements `since..to`: every commit reachable from to that
+// isn't also reachable from since, i.e. not in since's ancestor set
+// (inclusive of since itself) - the same set `git log since..to` walks,
+// regardless of how the two revisions' histories have diverged.
+func rangeCommits(repo *git.Repository, sinceRev, toRev string) ([]Commit, error) {
+	since, err := resolve(repo, sinceRev)
+	if err != nil {
+		return nil, err
+	}
+	to, err := resolve(repo, toRev)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := ancestorSet(repo, since)
+	if err != nil {
+		return nil, err
+	}
+	return logCommits(repo, to, exclude)
+}
+
+// symmetricDiffCommits implements `a...b`: commits reachable from exactly
+// one of a or b. This is computed directly from each side's ancestor set
+// rather than by rewriting `a...b` into `a..b`, which is a different (and,
+// once the two revisions have diverged, wrong) set of commits.
+func symmetricDiffCommits(repo *git.Repository, aRev, bRev string) ([]Commit, error) {
+	a, err := resolve(repo, aRev)
+	if err != nil {
+		return nil, err
+	}
+	b, err := resolve(repo, bRev)
+	if err != nil {
+		return nil, err
+	}
+	ancestorsA, err := ancestorSet(repo, a)
+	if err != nil {
+		return nil, err
+	}
+	ancestorsB, err := ancestorSet(repo, b)
+	if err != nil {
+		return nil, err
+	}
+
+	onlyB, err := logCommits(repo, b, ancestorsA)
+	if err != nil {
+		return nil, err
+	}
+	onlyA, err := logCommits(repo, a, ancestorsB)
+	if err != nil {
+		return nil, err
+	}
+	return append(onlyB, onlyA...), nil
+}
+
+// ancestorSet returns every commit hash reachable from from (inclusive),
+// following all parents of merge commits - not just "first parent" - so
+// range exclusion is correct across merges.
+func ancestorSet(repo *git.Repository, from plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	seen := map[plumbing.Hash]bool{}
+	stack := []plumbing.Hash{from}
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		c, err := repo.CommitObject(h)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range c.ParentHashes {
+			if !seen[p] {
+				stack = append(stack, p)
+			}
+		}
+	}
+	return seen, nil
+}
+
+// logCommits walks every commit reachable from from, skipping any hash in
+// exclude, and converts the survivors to Commit.
+func logCommits(repo *git.Repository, from plumbing.Hash, exclude map[plumbing.Hash]bool) ([]Commit, error) {
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var commits []Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if exclude[c.Hash] {
+			return nil
+		}
+		commits = append(commits, commitFromObject(c))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+func resolve(repo *git.Repository, rev string) (plumbing.Hash, error) {
+	h, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return *h, nil
+}
+
+func commitFromObject(c *object.Commit) Commit {
+	parents := make([]string, len(c.ParentHashes))
+	for i, p := range c.ParentHashes {
+		parents[i] = p.String()
+	}
+	subject, body := c.Message, ""
+	if i := strings.Index(c.Message, "\n\n"); i >= 0 {
+		subject, body = c.Message[:i], strings.TrimSpace(c.Message[i+2:])
+	}
+	return Commit{
+		Hash:    c.Hash.String(),
+		Parents: parents,
+		Author: Signature{
+			Name:  c.Author.Name,
+			Email: c.Author.Email,
+			Date:  c.Author.When,
+		},
+		Committer: Signature{
+			Name:  c.Committer.Name,
+			Email: c.Committer.Email,
+			Date:  c.Committer.When,
+		},
+		Subject: strings.TrimSpace(subject),
+		Body:    body,
+	}
+}
+
+// Check implements Backend. go-git has no equivalent to `git log --check`,
+// so this reports whitespace errors are unsupported on this backend rather
+// than silently returning an empty, misleadingly-clean result.
+func (b *GoGitBackend) Check(hash string) ([]byte, error) {
+	return nil, errors.New("git: Check is not implemented by GoGitBackend, use ShellBackend")
+}
+
+// Show implements Backend by rendering a unified diff of hash against its
+// first parent, in the same textual form `git show` produces for rule
+// authors further down the pipeline (e.g. diff.ParseMultiFileDiff).
+func (b *GoGitBackend) Show(hash string) ([]byte, error) {
+	repo, err := b.open()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := patch.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Head implements Backend.
+func (b *GoGitBackend) Head() (string, error) {
+	repo, err := b.open()
+	if err != nil {
+		return "", err
+	}
+	ref, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+// FetchHead implements Backend by reading FETCH_HEAD directly, since go-git
+// doesn't expose it through a typed API.
+func (b *GoGitBackend) FetchHead() (string, error) {
+	repo, err := b.open()
+	if err != nil {
+		return "", err
+	}
+	ref, err := repo.Reference(plumbing.ReferenceName("FETCH_HEAD"), true)
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
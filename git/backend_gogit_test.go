@@ -0,0 +1,121 @@
+package git
+
+import (
+	"context"
+	"os/exec"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// initMergeTestRepo builds a small branching history in dir:
+//
+//	base --- mainline  (main)
+//	   \       \
+//	    feature-+--- merge  (main, after merge)
+//
+// and returns the hash of each named commit.
+func initMergeTestRepo(t *testing.T, dir string) (base, feature, mainline, merge string) {
+	t.Helper()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+
+	run("commit", "--allow-empty", "-q", "-m", "base")
+	base = run("rev-parse", "HEAD")
+
+	run("checkout", "-q", "-b", "feature")
+	run("commit", "--allow-empty", "-q", "-m", "feature commit")
+	feature = run("rev-parse", "HEAD")
+
+	run("checkout", "-q", "-")
+	run("commit", "--allow-empty", "-q", "-m", "mainline commit")
+	mainline = run("rev-parse", "HEAD")
+
+	run("merge", "--no-ff", "-q", "-m", "merge feature", "feature")
+	merge = run("rev-parse", "HEAD")
+
+	return base, feature, mainline, merge
+}
+
+// shellCommitHashes returns the hash of every commit r.CommitsContext
+// (the shell-backed, hand-rolled `git log` path) returns for commitrange,
+// as the ground truth GoGitBackend is compared against.
+func shellCommitHashes(t *testing.T, r *Repo, commitrange string) []string {
+	t.Helper()
+	entries, err := r.CommitsContext(context.Background(), commitrange)
+	if err != nil {
+		t.Fatalf("CommitsContext(%q): %v", commitrange, err)
+	}
+	hashes := make([]string, len(entries))
+	for i, e := range entries {
+		hashes[i] = e["commit"]
+	}
+	return hashes
+}
+
+func goGitCommitHashes(t *testing.T, dir, commitrange string) []string {
+	t.Helper()
+	b := &GoGitBackend{Dir: dir}
+	commits, err := b.Commits(commitrange)
+	if err != nil {
+		t.Fatalf("GoGitBackend.Commits(%q): %v", commitrange, err)
+	}
+	hashes := make([]string, len(commits))
+	for i, c := range commits {
+		hashes[i] = c.Hash
+	}
+	return hashes
+}
+
+func sortedCopy(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
+
+func TestGoGitBackendMatchesShellForRange(t *testing.T) {
+	dir := t.TempDir()
+	base, _, _, merge := initMergeTestRepo(t, dir)
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	commitrange := base + ".." + merge
+	want := sortedCopy(shellCommitHashes(t, repo, commitrange))
+	got := sortedCopy(goGitCommitHashes(t, dir, commitrange))
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("GoGitBackend.Commits(%q) = %v, want (matching shell) %v", commitrange, got, want)
+	}
+	if len(want) != 3 {
+		t.Fatalf("shell ground truth returned %d commits, want 3 (merge + both branches)", len(want))
+	}
+}
+
+func TestGoGitBackendMatchesShellForSymmetricDiff(t *testing.T) {
+	dir := t.TempDir()
+	_, feature, mainline, _ := initMergeTestRepo(t, dir)
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	commitrange := feature + "..." + mainline
+	want := sortedCopy(shellCommitHashes(t, repo, commitrange))
+	got := sortedCopy(goGitCommitHashes(t, dir, commitrange))
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("GoGitBackend.Commits(%q) = %v, want (matching shell) %v", commitrange, got, want)
+	}
+	if len(want) != 2 {
+		t.Fatalf("shell ground truth returned %d commits, want 2 (one per diverged branch)", len(want))
+	}
+}
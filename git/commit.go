@@ -0,0 +1,114 @@
+package git
+
+import (
+	"strings"
+	"time"
+)
+
+// Commit is a strongly typed view of a single commit, following the shape
+// of maintner's GitCommit and dehub's GitCommit. Where CommitEntry leaves
+// rule authors string-sniffing a map, Commit gives them real types to match
+// on.
+type Commit struct {
+	Hash      string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	Subject   string
+	Body      string
+	Notes     string
+	Refs      []string
+	GPG       GPGSignature
+}
+
+// Signature is the name, email and timestamp of a commit's author or
+// committer.
+type Signature struct {
+	Name  string
+	Email string
+	Date  time.Time
+}
+
+// GPGSignature is a commit's GPG/SSH signing metadata, as reported by
+// `git log`'s %G? / %GS / %GK format codes.
+type GPGSignature struct {
+	Flag   rune
+	Signer string
+	Key    string
+}
+
+// commitDateLayout matches the format `git log`'s %aD/%cD emit: RFC2822,
+// but (unlike time.RFC1123Z, which hardcodes a zero-padded "02" day) with
+// a day-of-month git does not zero-pad, e.g. "Sun, 7 Jan 2024 10:00:00
+// +0000". Go's "2" reference-day layout element accepts both 1 and 2
+// digit days, so it parses git's output regardless of padding.
+const commitDateLayout = "Mon, 2 Jan 2006 15:04:05 -0700"
+
+// Parse converts a CommitEntry into a typed Commit, parsing %aD/%cD as
+// RFC2822 dates and splitting %P on spaces.
+func (c CommitEntry) Parse() (*Commit, error) {
+	commit := &Commit{
+		Hash:    c["commit"],
+		Subject: c["subject"],
+		Body:    c["body"],
+		Notes:   c["commit_notes"],
+	}
+
+	if p := strings.TrimSpace(c["parent"]); p != "" {
+		commit.Parents = strings.Fields(p)
+	}
+	if r := strings.TrimSpace(c["refs"]); r != "" {
+		for _, ref := range strings.Split(r, ",") {
+			commit.Refs = append(commit.Refs, strings.TrimSpace(ref))
+		}
+	}
+
+	author, err := parseSignature(c["author_name"], c["author_email"], c["author_date"])
+	if err != nil {
+		return nil, err
+	}
+	commit.Author = author
+
+	committer, err := parseSignature(c["committer_name"], c["committer_email"], c["committer_date"])
+	if err != nil {
+		return nil, err
+	}
+	commit.Committer = committer
+
+	if flag := c["verification_flag"]; flag != "" {
+		commit.GPG.Flag = rune(flag[0])
+	}
+	commit.GPG.Signer = c["signer"]
+	commit.GPG.Key = c["signer_key"]
+
+	return commit, nil
+}
+
+func parseSignature(name, email, date string) (Signature, error) {
+	sig := Signature{Name: name, Email: email}
+	if date == "" {
+		return sig, nil
+	}
+	t, err := time.Parse(commitDateLayout, date)
+	if err != nil {
+		return Signature{}, err
+	}
+	sig.Date = t
+	return sig, nil
+}
+
+// CommitsTyped is CommitsInRange's typed counterpart: it returns fully
+// parsed Commits rather than raw CommitEntry maps, dispatching through the
+// default Backend (see SetDefaultBackend).
+func CommitsTyped(commitrange string) ([]Commit, error) {
+	return defaultBackend.Commits(commitrange)
+}
+
+// LogCommitTyped is LogCommit's typed counterpart.
+func LogCommitTyped(commit string) (*Commit, error) {
+	entry, err := LogCommit(commit)
+	if err != nil {
+		return nil, err
+	}
+	return entry.Parse()
+}
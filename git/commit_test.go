@@ -0,0 +1,77 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommitEntryParse(t *testing.T) {
+	entry := CommitEntry{
+		"commit":            "abc123",
+		"parent":            "def456 789abc",
+		"subject":           "fix: something",
+		"body":              "a longer explanation",
+		"commit_notes":      "a note",
+		"refs":              "HEAD -> main, origin/main",
+		"author_name":       "Jane Doe",
+		"author_email":      "jane@example.com",
+		"author_date":       "Sun, 7 Jan 2024 10:00:00 +0000",
+		"committer_name":    "Jane Doe",
+		"committer_email":   "jane@example.com",
+		"committer_date":    "Mon, 17 Jun 2024 09:05:03 +0000",
+		"verification_flag": "G",
+		"signer":            "Jane Doe <jane@example.com>",
+		"signer_key":        "0123456789ABCDEF",
+	}
+
+	c, err := entry.Parse()
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if c.Hash != "abc123" {
+		t.Errorf("Hash = %q, want abc123", c.Hash)
+	}
+	if want := []string{"def456", "789abc"}; !equalSlices(c.Parents, want) {
+		t.Errorf("Parents = %v, want %v", c.Parents, want)
+	}
+	if want := []string{"HEAD -> main", "origin/main"}; !equalSlices(c.Refs, want) {
+		t.Errorf("Refs = %v, want %v", c.Refs, want)
+	}
+
+	wantAuthorDate := time.Date(2024, time.January, 7, 10, 0, 0, 0, time.UTC)
+	if !c.Author.Date.Equal(wantAuthorDate) {
+		t.Errorf("Author.Date = %v, want %v (single-digit day)", c.Author.Date, wantAuthorDate)
+	}
+	wantCommitterDate := time.Date(2024, time.June, 17, 9, 5, 3, 0, time.UTC)
+	if !c.Committer.Date.Equal(wantCommitterDate) {
+		t.Errorf("Committer.Date = %v, want %v", c.Committer.Date, wantCommitterDate)
+	}
+
+	if c.GPG.Flag != 'G' || c.GPG.Signer != "Jane Doe <jane@example.com>" || c.GPG.Key != "0123456789ABCDEF" {
+		t.Errorf("GPG = %+v, want Flag=G Signer=%q Key=%q", c.GPG, "Jane Doe <jane@example.com>", "0123456789ABCDEF")
+	}
+}
+
+func TestParseSignatureSingleDigitDay(t *testing.T) {
+	sig, err := parseSignature("Jane Doe", "jane@example.com", "Sun, 7 Jan 2024 10:00:00 +0000")
+	if err != nil {
+		t.Fatalf("parseSignature with single-digit day: %v", err)
+	}
+	want := time.Date(2024, time.January, 7, 10, 0, 0, 0, time.UTC)
+	if !sig.Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", sig.Date, want)
+	}
+}
+
+func equalSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,9 @@
 package git
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,31 +12,121 @@ import (
 	"github.com/Sirupsen/logrus"
 )
 
-// Commits returns a set of commits.
-// If commitrange is a git still range 12345...54321, then it will be isolated set of commits.
-// If commitrange is a single commit, all ancestor commits up through the hash provided.
-func Commits(commitrange string) ([]CommitEntry, error) {
-	cmdArgs := []string{"git", "--no-pager", "log", `--pretty=format:%H`, commitrange}
+// fieldOrder fixes the order in which FieldNames' keys are written into the
+// batched `git log` pretty-format template and therefore the order fields
+// must be read back out of a scanned record. FieldNames is a map (so its
+// iteration order isn't stable), so the wire order lives here instead.
+// %b (body) is last because it is the only multi-line field; anything after
+// it in a record would be impossible to split out reliably.
+var fieldOrder = []string{
+	"%H", "%h", "%P", "%p", "%t",
+	"%aN", "%aE", "%aD",
+	"%cN", "%cE", "%cD",
+	"%e", "%D", "%s", "%f",
+	"%G?", "%GS", "%GK",
+	"%N", "%b",
+}
+
+// fieldSep and recordSep are the raw byte sequences `git log` emits for the
+// %x1f/%x1e%x00 escapes below, used to delimit fields within a commit
+// record and commit records within the stream once they come back on
+// stdout. %x1e%x00 (RS then NUL) is used between commits since a lone %x1e
+// is technically representable inside a maliciously crafted commit message,
+// but the RS/NUL pair is not something `git log` otherwise produces.
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e\x00"
+)
+
+// batchPrettyFormat builds the `--pretty=format:` template used to fetch
+// every field in fieldOrder for every commit in a range with a single `git
+// log` invocation. It uses git's %x1f/%x1e/%x00 *escape syntax* (plain
+// ASCII text in the argument we pass to git), not the raw bytes themselves
+// -- an embedded NUL byte in an exec.Command argument is invalid and would
+// make every call fail.
+func batchPrettyFormat() string {
+	return strings.Join(fieldOrder, "%x1f") + "%x1e%x00"
+}
+
+// splitRecords is a bufio.SplitFunc that splits a batched `git log` stream
+// on recordSep, one token per commit.
+func splitRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.Index(data, []byte(recordSep)); i >= 0 {
+		return i + len(recordSep), data[0:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// runBatchedLog runs `git <gitArgs...>`, where gitArgs is expected to
+// already include the batched pretty format, and parses its output into
+// one CommitEntry per record.
+func runBatchedLog(ctx context.Context, gitArgs []string) ([]CommitEntry, error) {
+	cmd := exec.CommandContext(ctx, "git", gitArgs...)
 	if debug() {
-		logrus.Infof("[git] cmd: %q", strings.Join(cmdArgs, " "))
+		logrus.Infof("[git] cmd: %q", strings.Join(cmd.Args, " "))
 	}
-	output, err := exec.Command(cmdArgs[0], cmdArgs[1:]...).Output()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		logrus.Errorf("mm[git] cmd: %q", strings.Join(cmdArgs, " "))
 		return nil, err
 	}
-	commitHashes := strings.Split(strings.TrimSpace(string(output)), "\n")
-	commits := make([]CommitEntry, len(commitHashes))
-	for i, commitHash := range commitHashes {
-		c, err := LogCommit(commitHash)
-		if err != nil {
-			return commits, err
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		logrus.Errorf("[git] cmd: %q", strings.Join(cmd.Args, " "))
+		return nil, err
+	}
+
+	var commits []CommitEntry
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	scanner.Split(splitRecords)
+	for scanner.Scan() {
+		record := strings.TrimRight(scanner.Text(), "\n")
+		if record == "" {
+			continue
 		}
-		commits[i] = *c
+		fields := strings.SplitN(record, fieldSep, len(fieldOrder))
+		c := CommitEntry{}
+		for i, key := range fieldOrder {
+			if i >= len(fields) {
+				break
+			}
+			c[FieldNames[key]] = strings.TrimSpace(fields[i])
+		}
+		commits = append(commits, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := cmd.Wait(); err != nil {
+		logrus.Errorf("[git] cmd: %q", strings.Join(cmd.Args, " "))
+		return nil, err
 	}
 	return commits, nil
 }
 
+// CommitsInRange returns the full information on every commit in commitrange
+// using a single `git log` invocation, rather than one `git log` per field
+// per commit. commitrange follows the same rules as Commits.
+func CommitsInRange(commitrange string) ([]CommitEntry, error) {
+	return cwdRepo().Commits(commitrange)
+}
+
+// Commits returns a set of commits.
+// If commitrange is a git still range 12345...54321, then it will be isolated set of commits.
+// If commitrange is a single commit, all ancestor commits up through the hash provided.
+func Commits(commitrange string) ([]CommitEntry, error) {
+	return CommitsInRange(commitrange)
+}
+
+// CommitsContext is Commits with a context.Context, so a long-running `git
+// log` over a large range can be cancelled.
+func CommitsContext(ctx context.Context, commitrange string) ([]CommitEntry, error) {
+	return cwdRepo().CommitsContext(ctx, commitrange)
+}
+
 // FieldNames are for the formating and rendering of the CommitEntry structs.
 // Keys here are from git log pretty format "format:..."
 var FieldNames = map[string]string{
@@ -62,6 +155,20 @@ var FieldNames = map[string]string{
 // Check warns if changes introduce whitespace errors.
 // Returns non-zero if any issues are found.
 func Check(commit string) ([]byte, error) {
+	return defaultBackend.Check(commit)
+}
+
+// CheckContext is Check with a context.Context, so it can be cancelled.
+// It always uses ShellBackend, since GoGitBackend doesn't implement Check.
+func CheckContext(ctx context.Context, commit string) ([]byte, error) {
+	return shellCheckContext(ctx, commit)
+}
+
+func shellCheck(commit string) ([]byte, error) {
+	return shellCheckContext(context.Background(), commit)
+}
+
+func shellCheckContext(ctx context.Context, commit string) ([]byte, error) {
 	args := []string{
 		"--no-pager", "log", "--check",
 		fmt.Sprintf("%s^..%s", commit, commit),
@@ -69,7 +176,7 @@ func Check(commit string) ([]byte, error) {
 	if exclude := os.Getenv("GIT_CHECK_EXCLUDE"); exclude != "" {
 		args = append(args, "--", ".", fmt.Sprintf(":(exclude)%s", exclude))
 	}
-	cmd := exec.Command("git", args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	if debug() {
 		logrus.Infof("[git] cmd: %q", strings.Join(cmd.Args, " "))
 	}
@@ -81,7 +188,22 @@ func Check(commit string) ([]byte, error) {
 //
 // NOTE: This could be expensive for very large commits.
 func Show(commit string) ([]byte, error) {
-	cmd := exec.Command("git", "--no-pager", "show", commit)
+	return defaultBackend.Show(commit)
+}
+
+// ShowContext is Show with a context.Context, so it can be cancelled.
+// It always uses ShellBackend, since GoGitBackend's Show has no subprocess
+// to cancel partway through.
+func ShowContext(ctx context.Context, commit string) ([]byte, error) {
+	return shellShowContext(ctx, commit)
+}
+
+func shellShow(commit string) ([]byte, error) {
+	return shellShowContext(context.Background(), commit)
+}
+
+func shellShowContext(ctx context.Context, commit string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "--no-pager", "show", commit)
 	if debug() {
 		logrus.Infof("[git] cmd: %q", strings.Join(cmd.Args, " "))
 	}
@@ -94,23 +216,15 @@ func Show(commit string) ([]byte, error) {
 type CommitEntry map[string]string
 
 // LogCommit assembles the full information on a commit from its commit hash
+// using a single `git log` invocation.
 func LogCommit(commit string) (*CommitEntry, error) {
-	c := CommitEntry{}
-	for k, v := range FieldNames {
-		cmd := exec.Command("git", "--no-pager", "log", "-1", `--pretty=format:`+k+``, commit)
-		if debug() {
-			logrus.Infof("[git] cmd: %q", strings.Join(cmd.Args, " "))
-		}
-		cmd.Stderr = os.Stderr
-		out, err := cmd.Output()
-		if err != nil {
-			logrus.Errorf("[git] cmd: %q", strings.Join(cmd.Args, " "))
-			return nil, err
-		}
-		c[v] = strings.TrimSpace(string(out))
-	}
+	return LogCommitContext(context.Background(), commit)
+}
 
-	return &c, nil
+// LogCommitContext is LogCommit with a context.Context, so it can be
+// cancelled.
+func LogCommitContext(ctx context.Context, commit string) (*CommitEntry, error) {
+	return cwdRepo().LogCommitContext(ctx, commit)
 }
 
 func debug() bool {
@@ -119,6 +233,10 @@ func debug() bool {
 
 // FetchHeadCommit returns the hash of FETCH_HEAD
 func FetchHeadCommit() (string, error) {
+	return defaultBackend.FetchHead()
+}
+
+func shellFetchHeadCommit() (string, error) {
 	cmdArgs := []string{"git", "--no-pager", "rev-parse", "--verify", "FETCH_HEAD"}
 	if debug() {
 		logrus.Infof("[git] cmd: %q", strings.Join(cmdArgs, " "))
@@ -133,6 +251,10 @@ func FetchHeadCommit() (string, error) {
 
 // HeadCommit returns the hash of HEAD
 func HeadCommit() (string, error) {
+	return defaultBackend.Head()
+}
+
+func shellHeadCommit() (string, error) {
 	cmdArgs := []string{"git", "--no-pager", "rev-parse", "--verify", "HEAD"}
 	if debug() {
 		logrus.Infof("[git] cmd: %q", strings.Join(cmdArgs, " "))
@@ -0,0 +1,35 @@
+package git
+
+import "testing"
+
+// BenchmarkCommits exercises CommitsInRange against this repository's own
+// history, which is the easiest stand-in for "a repo with hundreds of
+// commits" available in CI. It's a useful relative comparison even on a
+// shallow checkout: it shows the one-`git log`-per-range cost instead of the
+// previous N (commits) x M (fields) subprocess fan-out.
+func BenchmarkCommits(b *testing.B) {
+	if _, err := HeadCommit(); err != nil {
+		b.Skipf("not a git checkout: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Commits("HEAD"); err != nil {
+			b.Fatalf("Commits: %v", err)
+		}
+	}
+}
+
+// BenchmarkLogCommit benchmarks fetching a single commit's full information,
+// for comparison against the per-field fan-out this replaced.
+func BenchmarkLogCommit(b *testing.B) {
+	head, err := HeadCommit()
+	if err != nil {
+		b.Skipf("not a git checkout: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LogCommit(head); err != nil {
+			b.Fatalf("LogCommit: %v", err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package git
+
+import "testing"
+
+func TestObjectFormatIsValid(t *testing.T) {
+	sha1Hash := "0123456789abcdef0123456789abcdef01234567"
+	sha256Hash := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	if !SHA1.IsValid(sha1Hash) {
+		t.Errorf("SHA1.IsValid(%q) = false, want true", sha1Hash)
+	}
+	if SHA1.IsValid(sha256Hash) {
+		t.Errorf("SHA1.IsValid(%q) = true, want false", sha256Hash)
+	}
+	if !SHA256.IsValid(sha256Hash) {
+		t.Errorf("SHA256.IsValid(%q) = false, want true", sha256Hash)
+	}
+	if SHA1.IsValid("not-hex") {
+		t.Errorf("SHA1.IsValid(%q) = true, want false", "not-hex")
+	}
+}
+
+func TestRepoValidateHashRejectsWrongFormat(t *testing.T) {
+	r := &Repo{Dir: ".", ObjectFormat: SHA1}
+	sha256Hash := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	if err := r.validateHash(sha256Hash); err == nil {
+		t.Errorf("validateHash(%q) on a SHA1 repo = nil, want error", sha256Hash)
+	}
+	if err := r.validateHash("HEAD"); err != nil {
+		t.Errorf("validateHash(%q) = %v, want nil (refs pass through)", "HEAD", err)
+	}
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSignatureType(t *testing.T) {
+	cases := []struct {
+		name   string
+		signer string
+		keyID  string
+		want   string
+	}{
+		{"ssh sha256 fingerprint", "", "SHA256:abcdef", "ssh"},
+		{"ssh sha1 fingerprint", "", "SHA1:abcdef", "ssh"},
+		{"x509 distinguished name", "CN=Jane Doe,O=Example Corp", "0123456789abcdef", "x509"},
+		{"gpg signer", "Jane Doe <jane@example.com>", "0123456789abcdef", "gpg"},
+		{"bare key id, no signer", "", "0123456789abcdef", "gpg"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := signatureType(c.signer, c.keyID); got != c.want {
+				t.Errorf("signatureType(%q, %q) = %q, want %q", c.signer, c.keyID, got, c.want)
+			}
+		})
+	}
+}
+
+// chdirToTestRepo creates a throwaway repo with a single unsigned commit,
+// chdirs the test process into it (restoring the original cwd on
+// cleanup), and returns the commit's hash. Verify/VerifyRange shell out
+// to `git` against the process cwd rather than taking a directory, so
+// this is the only way to point them at a test repo.
+func chdirToTestRepo(t *testing.T) (hash string) {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+	run("commit", "--allow-empty", "-q", "-m", "unsigned commit")
+	hash = run("rev-parse", "HEAD")
+
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("os.Getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("os.Chdir(%q): %v", dir, err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(oldwd); err != nil {
+			t.Fatalf("os.Chdir(%q): %v", oldwd, err)
+		}
+	})
+	return hash
+}
+
+func TestVerifyUnsignedCommit(t *testing.T) {
+	hash := chdirToTestRepo(t)
+
+	if _, err := Verify(hash); err != ErrNoSignature {
+		t.Errorf("Verify(%q) error = %v, want ErrNoSignature", hash, err)
+	}
+}
+
+func TestVerifyRangeUnsignedCommit(t *testing.T) {
+	hash := chdirToTestRepo(t)
+
+	verifications, err := VerifyRange(hash)
+	if err != nil {
+		t.Fatalf("VerifyRange: %v", err)
+	}
+	if len(verifications) != 1 {
+		t.Fatalf("got %d verifications, want 1", len(verifications))
+	}
+	v := verifications[0]
+	if v.Good || v.Flag != 'N' {
+		t.Errorf("verification = %+v, want Good=false Flag='N'", v)
+	}
+}
@@ -0,0 +1,109 @@
+// Package hook turns git-validation into a server-side push gate: it
+// parses the lines a pre-receive hook receives on stdin and runs the same
+// kind of validation rules CI would run against each updated ref's new
+// commits.
+package hook
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mikebrow/git-validation/git"
+)
+
+// Rule validates the commits introduced on a single updated ref.
+type Rule interface {
+	// Name identifies the rule in a Result.
+	Name() string
+	// Validate inspects commits (newest first, as git log returns them)
+	// and returns one problem string per failing commit, or nil if every
+	// commit passes.
+	Validate(commits []git.Commit) []string
+}
+
+// Result is one Rule's outcome against one updated ref.
+type Result struct {
+	Rule     string
+	Ref      string
+	Range    string
+	Problems []string
+}
+
+// Pass reports whether Result found no problems.
+func (r Result) Pass() bool {
+	return len(r.Problems) == 0
+}
+
+// RunPreReceive parses the `<old-oid> <new-oid> <ref>` lines git feeds a
+// pre-receive hook on stdin and runs rules against the commits introduced
+// on each updated ref. repo's ObjectFormat determines the zero OID to
+// compare against, since a SHA-256 repository's pre-receive stdin uses a
+// 64-char all-zero OID rather than SHA-1's 40-char one.
+//
+// Branch creation (old-oid all zero) validates every commit reachable from
+// new-oid, since there's no prior tip to range from. Branch deletion
+// (new-oid all zero) is skipped, since no commits are being introduced.
+func RunPreReceive(ctx context.Context, r io.Reader, repo *git.Repo, rules []Rule) ([]Result, error) {
+	zeroOID := repo.ObjectFormat.ZeroID()
+	var results []Result
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return results, fmt.Errorf("hook: malformed pre-receive line %q", line)
+		}
+		oldOID, newOID, ref := fields[0], fields[1], fields[2]
+		if newOID == zeroOID {
+			continue
+		}
+
+		commitrange := newOID
+		if oldOID != zeroOID {
+			commitrange = oldOID + ".." + newOID
+		}
+
+		commits, err := commitsInRange(ctx, repo, commitrange)
+		if err != nil {
+			return results, err
+		}
+
+		for _, rule := range rules {
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+			results = append(results, Result{
+				Rule:     rule.Name(),
+				Ref:      ref,
+				Range:    commitrange,
+				Problems: rule.Validate(commits),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+func commitsInRange(ctx context.Context, repo *git.Repo, commitrange string) ([]git.Commit, error) {
+	entries, err := repo.CommitsContext(ctx, commitrange)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]git.Commit, len(entries))
+	for i, e := range entries {
+		c, err := e.Parse()
+		if err != nil {
+			return nil, err
+		}
+		commits[i] = *c
+	}
+	return commits, nil
+}
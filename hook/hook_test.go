@@ -0,0 +1,128 @@
+package hook
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mikebrow/git-validation/git"
+)
+
+// recordingRule is a Rule that records the subjects it was asked to
+// validate and reports no problems, so tests can assert on which commits
+// RunPreReceive fed it.
+type recordingRule struct {
+	subjects []string
+}
+
+func (r *recordingRule) Name() string { return "recording" }
+
+func (r *recordingRule) Validate(commits []git.Commit) []string {
+	for _, c := range commits {
+		r.subjects = append(r.subjects, c.Subject)
+	}
+	return nil
+}
+
+// initTestRepo creates a throwaway git repo with two commits (first,
+// second) in dir and returns their hashes.
+func initTestRepo(t *testing.T, dir string) (first, second string) {
+	t.Helper()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+	run("commit", "--allow-empty", "-q", "-m", "first commit")
+	first = run("rev-parse", "HEAD")
+	run("commit", "--allow-empty", "-q", "-m", "second commit")
+	second = run("rev-parse", "HEAD")
+	return first, second
+}
+
+func TestRunPreReceiveCreate(t *testing.T) {
+	dir := t.TempDir()
+	_, second := initTestRepo(t, dir)
+	repo, err := git.Open(dir)
+	if err != nil {
+		t.Fatalf("git.Open: %v", err)
+	}
+
+	rule := &recordingRule{}
+	zero := repo.ObjectFormat.ZeroID()
+	stdin := strings.NewReader(zero + " " + second + " refs/heads/main\n")
+	results, err := RunPreReceive(context.Background(), stdin, repo, []Rule{rule})
+	if err != nil {
+		t.Fatalf("RunPreReceive: %v", err)
+	}
+	if len(results) != 1 || !results[0].Pass() {
+		t.Fatalf("results = %+v, want one passing result", results)
+	}
+	if want := []string{"second commit", "first commit"}; !equalStrings(rule.subjects, want) {
+		t.Errorf("validated subjects = %v, want %v", rule.subjects, want)
+	}
+}
+
+func TestRunPreReceiveDelete(t *testing.T) {
+	dir := t.TempDir()
+	_, second := initTestRepo(t, dir)
+	repo, err := git.Open(dir)
+	if err != nil {
+		t.Fatalf("git.Open: %v", err)
+	}
+
+	rule := &recordingRule{}
+	zero := repo.ObjectFormat.ZeroID()
+	stdin := strings.NewReader(second + " " + zero + " refs/heads/main\n")
+	results, err := RunPreReceive(context.Background(), stdin, repo, []Rule{rule})
+	if err != nil {
+		t.Fatalf("RunPreReceive: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("results = %+v, want none (deletion introduces no commits)", results)
+	}
+	if len(rule.subjects) != 0 {
+		t.Errorf("validated subjects = %v, want none", rule.subjects)
+	}
+}
+
+func TestRunPreReceiveUpdate(t *testing.T) {
+	dir := t.TempDir()
+	first, second := initTestRepo(t, dir)
+	repo, err := git.Open(dir)
+	if err != nil {
+		t.Fatalf("git.Open: %v", err)
+	}
+
+	rule := &recordingRule{}
+	stdin := strings.NewReader(first + " " + second + " refs/heads/main\n")
+	results, err := RunPreReceive(context.Background(), stdin, repo, []Rule{rule})
+	if err != nil {
+		t.Fatalf("RunPreReceive: %v", err)
+	}
+	if len(results) != 1 || !results[0].Pass() {
+		t.Fatalf("results = %+v, want one passing result", results)
+	}
+	if want := []string{"second commit"}; !equalStrings(rule.subjects, want) {
+		t.Errorf("validated subjects = %v, want %v", rule.subjects, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}